@@ -4,6 +4,13 @@
 
 package ssa
 
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
 type branch int
 
 const (
@@ -62,14 +69,85 @@ type pair struct {
 
 // fact is a pair plus a relation for that pair.
 type fact struct {
-	p pair
-	r relation
+	p       pair
+	r       relation
+	origin  string  // how this (now superseded) fact was derived; see factInfo
+	trans   bool    // see factInfo.trans
+	parents [2]pair // see factInfo.parents
+}
+
+// factInfo is what factsTable.facts stores for a pair: the known
+// relation plus a description of how it was derived, so traces (see
+// emitProveTrace) can cite provenance: a dominator edge or "indvar"
+// for directly-learned facts, or, when trans is true, the pair of
+// facts in parents whose composition (via closeTransitively) produced
+// r, so a trace can walk the chain back to the facts that were
+// actually learned from a branch or induction variable.
+type factInfo struct {
+	r       relation
+	origin  string
+	trans   bool
+	parents [2]pair
 }
 
+// limit tracks the known range of values a *Value can take, both as
+// a signed and as an unsigned integer: min <= v <= max (signed) and
+// umin <= v <= umax (unsigned, using the value's own bit pattern).
+// The two views are tracked independently because a fact learned in
+// one domain (e.g. unsigned, from an IsInBounds check) does not
+// transfer to the other.
+type limit struct {
+	min, max   int64
+	umin, umax uint64
+}
+
+const (
+	minInt64 = -1 << 63
+	maxInt64 = 1<<63 - 1
+)
+
+// noLimit is the most permissive limit: nothing is known.
+var noLimit = limit{minInt64, maxInt64, 0, ^uint64(0)}
+
+// intersect returns the tightest limit implied by both l and l2.
+func (l limit) intersect(l2 limit) limit {
+	if l2.min > l.min {
+		l.min = l2.min
+	}
+	if l2.max < l.max {
+		l.max = l2.max
+	}
+	if l2.umin > l.umin {
+		l.umin = l2.umin
+	}
+	if l2.umax < l.umax {
+		l.umax = l2.umax
+	}
+	return l
+}
+
+// limitFact records the previous limit known for a value, so that
+// it can be restored on backtrack. ok reports whether vid had an
+// entry in factsTable.limits before the update that pushed this
+// limitFact; if not, restore deletes the entry rather than
+// resurrecting a stale noLimit.
+type limitFact struct {
+	vid ID
+	old limit
+	ok  bool
+}
+
+// checkpointBound is an invalid value used for checkpointing
+// and restoring factsTable.limits, symmetric with checkpointFact.
+var checkpointBound = limitFact{}
+
 // factsTable keeps track of relations between pairs of values.
 type factsTable struct {
-	facts map[pair]relation // current known set of relation
+	facts map[pair]factInfo // current known set of relations, with provenance
 	stack []fact            // previous sets of relations
+
+	limits     map[ID]limit // current known intervals, by value ID
+	limitStack []limitFact  // previous intervals, for restore
 }
 
 // checkpointFact is an invalid value used for checkpointing
@@ -78,8 +156,10 @@ var checkpointFact = fact{}
 
 func newFactsTable() *factsTable {
 	ft := &factsTable{}
-	ft.facts = make(map[pair]relation)
+	ft.facts = make(map[pair]factInfo)
 	ft.stack = make([]fact, 4)
+	ft.limits = make(map[ID]limit)
+	ft.limitStack = make([]limitFact, 4)
 	return ft
 }
 
@@ -93,7 +173,8 @@ func (ft *factsTable) get(v, w *Value, d domain) relation {
 	}
 
 	p := pair{v, w, d}
-	r, ok := ft.facts[p]
+	fi, ok := ft.facts[p]
+	r := fi.r
 	if !ok {
 		if p.v == p.w {
 			r = eq
@@ -109,8 +190,23 @@ func (ft *factsTable) get(v, w *Value, d domain) relation {
 }
 
 // update updates the set of relations between v and w in domain d
-// restricting it to r.
-func (ft *factsTable) update(v, w *Value, d domain, r relation) {
+// restricting it to r, then propagates the tightened relation to any
+// other fact sharing an endpoint with v or w (a lightweight
+// congruence closure), so that e.g. learning a < b and b <= c
+// immediately yields a < c without either comparison appearing
+// explicitly again. origin is a short human-readable description of
+// why this fact was learned (a dominator edge or "indvar"), recorded
+// for later use by a prove trace.
+func (ft *factsTable) update(v, w *Value, d domain, r relation, origin string) {
+	ft.updateDerived(v, w, d, r, origin, false, [2]pair{})
+}
+
+// updateDerived is update, plus bookkeeping for facts derived
+// transitively by closeTransitively: trans and parents record that r
+// came from composing the two facts in parents, so a prove trace can
+// walk back to the facts that were actually learned from a branch or
+// induction variable instead of citing a collapsed description.
+func (ft *factsTable) updateDerived(v, w *Value, d domain, r relation, origin string, trans bool, parents [2]pair) {
 	if lessByID(w, v) {
 		v, w = w, v
 		r = reverseBits[r]
@@ -118,14 +214,128 @@ func (ft *factsTable) update(v, w *Value, d domain, r relation) {
 
 	p := pair{v, w, d}
 	oldR := ft.get(v, w, d)
-	ft.stack = append(ft.stack, fact{p, oldR})
-	ft.facts[p] = oldR & r
+	newR := oldR & r
+	if newR == oldR {
+		// Nothing new to learn, and no need to re-run closure.
+		return
+	}
+	old := ft.facts[p]
+	ft.stack = append(ft.stack, fact{p, oldR, old.origin, old.trans, old.parents})
+	ft.facts[p] = factInfo{newR, origin, trans, parents}
+
+	ft.closeTransitively(v, w, d, newR)
+}
+
+// transitiveTable composes two base relations (lt, eq or gt) of a
+// chain a ? b ? c into what that implies about a ? c. A result of
+// lt|eq|gt means the chain implies nothing.
+var transitiveTable = [3][3]relation{
+	// b ? c:   lt           eq           gt
+	/* a?b=lt */ {lt, lt, lt | eq | gt},
+	/* a?b=eq */ {lt, eq, gt},
+	/* a?b=gt */ {lt | eq | gt, gt, gt},
+}
+
+// composeRelation returns what a ? c can be inferred to be, given
+// a ? b is (a subset of) r1 and b ? c is (a subset of) r2, by
+// composing every pair of base relations the two sets admit.
+func composeRelation(r1, r2 relation) relation {
+	bits := [3]relation{lt, eq, gt}
+	var out relation
+	for i, b1 := range bits {
+		if r1&b1 == 0 {
+			continue
+		}
+		for j, b2 := range bits {
+			if r2&b2 == 0 {
+				continue
+			}
+			out |= transitiveTable[i][j]
+		}
+	}
+	return out
+}
+
+// closeTransitiveFactsLimit bounds how many live facts
+// closeTransitively will scan through per update call. Without it, a
+// function with many chained comparisons would make every update (and
+// prove runs on every compiled function) rescan the whole, ever-growing
+// facts map, an unbounded cost beyond what this lightweight closure is
+// meant to spend.
+const closeTransitiveFactsLimit = 500
+
+// closeTransitively derives new facts implied by the newly recorded
+// relation v r w together with any existing fact in domain d that
+// shares v or w as an endpoint. Derived facts are pushed through
+// updateDerived itself (and so land on the same undo stack, unwound by
+// a single restore) so the closure is symmetric with the checkpoint
+// mechanism; it terminates because updateDerived is a no-op once a
+// pair's relation stops shrinking. Each derived fact records the pair
+// {v,w} and the other pair it was composed with as its parents, so a
+// prove trace can walk back to the facts that were actually learned
+// from a branch or induction variable.
+func (ft *factsTable) closeTransitively(v, w *Value, d domain, r relation) {
+	if len(ft.facts) > closeTransitiveFactsLimit {
+		// Bail rather than let a function with many chained
+		// comparisons turn every update into a full-table rescan;
+		// directly-learned facts are unaffected, only further
+		// transitive propagation is skipped.
+		return
+	}
+
+	self := pair{v, w, d}
+	var others []pair
+	for p := range ft.facts {
+		if p.d == d && p != self && (p.v == v || p.v == w || p.w == v || p.w == w) {
+			others = append(others, p)
+		}
+	}
+	// Map iteration order is randomized, but which derivation "wins"
+	// a pair's recorded origin depends on visit order (update is a
+	// no-op once a relation stops shrinking). Sort so the order, and
+	// hence the origin string a later prove trace cites, is stable
+	// across runs of the same function.
+	sort.Slice(others, func(i, j int) bool {
+		if others[i].v != others[j].v {
+			return lessByID(others[i].v, others[j].v)
+		}
+		return lessByID(others[i].w, others[j].w)
+	})
+
+	parents := [2]pair{self, pair{}}
+	for _, e := range others {
+		r2 := ft.get(e.v, e.w, d)
+		parents[1] = e
+		switch {
+		case e.w == v:
+			// e.v ? v (r2), v ? w (r) => e.v ? w
+			if nr := composeRelation(r2, r); nr != lt|eq|gt {
+				ft.updateDerived(e.v, w, d, nr, "transitive", true, parents)
+			}
+		case e.v == w:
+			// v ? w (r), w ? e.w (r2) => v ? e.w
+			if nr := composeRelation(r, r2); nr != lt|eq|gt {
+				ft.updateDerived(v, e.w, d, nr, "transitive", true, parents)
+			}
+		case e.v == v:
+			// e.w ? v (rev r2), v ? w (r) => e.w ? w
+			if nr := composeRelation(reverseBits[r2], r); nr != lt|eq|gt {
+				ft.updateDerived(e.w, w, d, nr, "transitive", true, parents)
+			}
+		case e.w == w:
+			// e.v ? w (r2), w ? v (rev r) => e.v ? v
+			if nr := composeRelation(r2, reverseBits[r]); nr != lt|eq|gt {
+				ft.updateDerived(e.v, v, d, nr, "transitive", true, parents)
+			}
+		}
+	}
 }
 
 // checkpoint saves the current state of known relations.
 // Called when descending on a branch.
 func (ft *factsTable) checkpoint() {
 	ft.stack = append(ft.stack, checkpointFact)
+	ft.limitStack = append(ft.limitStack, checkpointBound)
 }
 
 // restore restores known relation to the state just
@@ -141,9 +351,304 @@ func (ft *factsTable) restore() {
 		if old.r == lt|eq|gt {
 			delete(ft.facts, old.p)
 		} else {
-			ft.facts[old.p] = old.r
+			ft.facts[old.p] = factInfo{old.r, old.origin, old.trans, old.parents}
+		}
+	}
+	for {
+		old := ft.limitStack[len(ft.limitStack)-1]
+		ft.limitStack = ft.limitStack[:len(ft.limitStack)-1]
+		if old == checkpointBound {
+			break
+		}
+		if old.ok {
+			ft.limits[old.vid] = old.old
+		} else {
+			delete(ft.limits, old.vid)
+		}
+	}
+}
+
+// limit returns the known interval for v, combining any interval
+// learned from branches with whatever can be derived structurally
+// from v's operation and its arguments' limits. As with pair, v may
+// be nil to mean the zero value (nil pointer or false), which has
+// the interval [0,0].
+func (ft *factsTable) limit(v *Value) limit {
+	if v == nil {
+		return limit{min: 0, max: 0, umin: 0, umax: 0}
+	}
+	if l, ok := ft.limits[v.ID]; ok {
+		return l
+	}
+	return ft.computeLimit(v)
+}
+
+// computeLimit derives a limit for v from its opcode, recursing into
+// the limits of its arguments. It ignores any facts learned from
+// branches (those are consulted by limit via ft.limits); it exists so
+// that structural facts like "x & 0xff is in [0,255]" are available
+// even for values with no recorded branch history.
+func (ft *factsTable) computeLimit(v *Value) limit {
+	l := noLimit
+	switch v.Op {
+	case OpConst64:
+		l.min, l.max = v.AuxInt, v.AuxInt
+		l.umin, l.umax = uint64(v.AuxInt), uint64(v.AuxInt)
+	case OpConst32:
+		c := int64(int32(v.AuxInt))
+		l.min, l.max = c, c
+		l.umin, l.umax = uint64(v.AuxInt)&0xffffffff, uint64(v.AuxInt)&0xffffffff
+	case OpConst16:
+		c := int64(int16(v.AuxInt))
+		l.min, l.max = c, c
+		l.umin, l.umax = uint64(v.AuxInt)&0xffff, uint64(v.AuxInt)&0xffff
+	case OpConst8:
+		c := int64(int8(v.AuxInt))
+		l.min, l.max = c, c
+		l.umin, l.umax = uint64(v.AuxInt)&0xff, uint64(v.AuxInt)&0xff
+	case OpStringLen, OpSliceLen, OpSliceCap:
+		l.min = 0
+	case OpZeroExt8to64, OpZeroExt16to64, OpZeroExt32to64,
+		OpZeroExt8to32, OpZeroExt16to32:
+		al := ft.limit(v.Args[0])
+		l.min = 0
+		if al.umax <= uint64(maxInt64) {
+			// al.umax might still be the "no info" sentinel
+			// ^uint64(0) (e.g. an untracked byte-typed value);
+			// casting that to int64 would wrap to -1 and claim
+			// the impossible interval [0, -1]. Only tighten max
+			// when the unsigned bound actually fits in an int64.
+			l.max = int64(al.umax)
+		}
+		l.umin, l.umax = al.umin, al.umax
+	case OpSignExt8to64, OpSignExt16to64, OpSignExt32to64:
+		al := ft.limit(v.Args[0])
+		l.min, l.max = al.min, al.max
+	case OpAnd64, OpAnd32, OpAnd16, OpAnd8:
+		al, bl := ft.limit(v.Args[0]), ft.limit(v.Args[1])
+		// AND can only clear bits, so the unsigned result is bounded
+		// by the tighter of the two operands' unsigned upper bounds.
+		// The signed minimum is NOT 0 in general (e.g. -1 & -1 == -1);
+		// only claim it when both operands are already known
+		// non-negative, in which case the unsigned bound applies to
+		// the signed range too.
+		l.umax = al.umax
+		if bl.umax < l.umax {
+			l.umax = bl.umax
+		}
+		if al.min >= 0 && bl.min >= 0 {
+			l.min = 0
+			if l.umax <= uint64(maxInt64) {
+				// As above, l.umax may still be the "no info"
+				// sentinel; only tighten max when it fits in int64.
+				l.max = int64(l.umax)
+			}
+		}
+	case OpRsh64x64, OpRsh32x64, OpRsh16x64, OpRsh8x64:
+		al := ft.limit(v.Args[0])
+		if al.min >= 0 {
+			l.min, l.max = 0, al.max
+		}
+	case OpAdd64:
+		al, bl := ft.limit(v.Args[0]), ft.limit(v.Args[1])
+		l.min, l.max = addLimits64(al.min, bl.min, al.max, bl.max)
+	case OpSub64:
+		al, bl := ft.limit(v.Args[0]), ft.limit(v.Args[1])
+		l.min, l.max = subLimits64(al.min, bl.min, al.max, bl.max)
+	case OpAdd32:
+		al, bl := ft.limit(v.Args[0]), ft.limit(v.Args[1])
+		l.min, l.max = addLimits32(al.min, bl.min, al.max, bl.max)
+	case OpSub32:
+		al, bl := ft.limit(v.Args[0]), ft.limit(v.Args[1])
+		l.min, l.max = subLimits32(al.min, bl.min, al.max, bl.max)
+	}
+	return l
+}
+
+// addLimits64 returns the [min,max] range of a 64-bit a+b given a in
+// [amin,amax] and b in [bmin,bmax]. Go integers wrap on overflow rather
+// than saturate, so when the sum could overflow int64 the true runtime
+// value could land anywhere after wrapping; in that case the result is
+// left fully unconstrained instead of reporting a clamped bound that the
+// wrapped value could fall outside of.
+func addLimits64(amin, bmin, amax, bmax int64) (min, max int64) {
+	lo, loOK := addOvf64(amin, bmin)
+	hi, hiOK := addOvf64(amax, bmax)
+	if !loOK || !hiOK {
+		return minInt64, maxInt64
+	}
+	return lo, hi
+}
+
+// addOvf64 returns a+b and whether the sum did not overflow int64.
+func addOvf64(a, b int64) (sum int64, ok bool) {
+	s := a + b
+	if (a >= 0) == (b >= 0) && (s >= 0) != (a >= 0) {
+		// Signs of a and b agree but the sum's sign disagrees: overflowed.
+		return 0, false
+	}
+	return s, true
+}
+
+// subLimits64 returns the [min,max] range of a 64-bit a-b given a in
+// [amin,amax] and b in [bmin,bmax]. Computed directly from subtraction
+// rather than via negation-then-add, since negating bmin or bmax can
+// itself overflow (minInt64 has no positive counterpart); folding that
+// into a saturating negation before checking the sum for overflow, as
+// an earlier version of this code did, let an overflowing negation
+// masquerade as an in-range operand and produce an unsound exact limit.
+func subLimits64(amin, bmin, amax, bmax int64) (min, max int64) {
+	lo, loOK := subOvf64(amin, bmax)
+	hi, hiOK := subOvf64(amax, bmin)
+	if !loOK || !hiOK {
+		return minInt64, maxInt64
+	}
+	return lo, hi
+}
+
+// subOvf64 returns a-b and whether the difference did not overflow int64.
+func subOvf64(a, b int64) (diff int64, ok bool) {
+	d := a - b
+	if (a >= 0) != (b >= 0) && (d >= 0) != (a >= 0) {
+		// Signs of a and b disagree but the difference's sign doesn't
+		// match a's: overflowed.
+		return 0, false
+	}
+	return d, true
+}
+
+const (
+	minInt32 = -1 << 31
+	maxInt32 = 1<<31 - 1
+)
+
+// addLimits32 returns the [min,max] range of a 32-bit a+b given a in
+// [amin,amax] and b in [bmin,bmax] (as sign-extended int64s, the same
+// representation computeLimit's OpConst32 case uses). Operands are first
+// clamped to the int32 range, since a 32-bit op's inputs can never truly
+// hold a wider value. If the 32-bit sum could overflow, the result is
+// widened to the full int32 range rather than clamped, for the same
+// wrap-vs-saturate reason as addLimits64: the wrapped runtime value must
+// remain inside whatever bound is reported.
+func addLimits32(amin, bmin, amax, bmax int64) (min, max int64) {
+	amin, amax = clampInt32(amin), clampInt32(amax)
+	bmin, bmax = clampInt32(bmin), clampInt32(bmax)
+	lo := amin + bmin
+	hi := amax + bmax
+	if lo < minInt32 || lo > maxInt32 || hi < minInt32 || hi > maxInt32 {
+		return minInt32, maxInt32
+	}
+	return lo, hi
+}
+
+// subLimits32 is addLimits32's counterpart for a 32-bit a-b, given a in
+// [amin,amax] and b in [bmin,bmax]. Like addLimits32, operands are
+// clamped to the int32 range before the subtraction so an overflowing
+// difference is detected directly rather than via a saturating negation
+// that could itself hide an overflow (see subLimits64).
+func subLimits32(amin, bmin, amax, bmax int64) (min, max int64) {
+	amin, amax = clampInt32(amin), clampInt32(amax)
+	bmin, bmax = clampInt32(bmin), clampInt32(bmax)
+	lo := amin - bmax
+	hi := amax - bmin
+	if lo < minInt32 || lo > maxInt32 || hi < minInt32 || hi > maxInt32 {
+		return minInt32, maxInt32
+	}
+	return lo, hi
+}
+
+// clampInt32 narrows v to the int32 range.
+func clampInt32(v int64) int64 {
+	if v < minInt32 {
+		return minInt32
+	}
+	if v > maxInt32 {
+		return maxInt32
+	}
+	return v
+}
+
+// constInt reports whether v is an integer constant, returning its
+// value sign-extended to int64.
+func constInt(v *Value) (c int64, ok bool) {
+	switch v.Op {
+	case OpConst64:
+		return v.AuxInt, true
+	case OpConst32:
+		return int64(int32(v.AuxInt)), true
+	case OpConst16:
+		return int64(int16(v.AuxInt)), true
+	case OpConst8:
+		return int64(int8(v.AuxInt)), true
+	}
+	return 0, false
+}
+
+// newLimit intersects v's known limit with l, recording the previous
+// value so restore can undo it.
+func (ft *factsTable) newLimit(v *Value, l limit) {
+	old, ok := ft.limits[v.ID]
+	ft.limitStack = append(ft.limitStack, limitFact{v.ID, old, ok})
+	if ok {
+		l = l.intersect(old)
+	}
+	ft.limits[v.ID] = l
+}
+
+// learnLimit refines known intervals given that the relation v r w
+// holds in domain d (signed or unsigned), when one side is constant.
+func (ft *factsTable) learnLimit(v, w *Value, d domain, r relation) {
+	if d != signed && d != unsigned {
+		return
+	}
+	if c, ok := constInt(w); ok {
+		ft.narrowFromConst(v, d, r, c)
+	}
+	if c, ok := constInt(v); ok {
+		ft.narrowFromConst(w, d, reverseBits[r], c)
+	}
+}
+
+// narrowFromConst records that v r c holds, in domain d.
+func (ft *factsTable) narrowFromConst(v *Value, d domain, r relation, c int64) {
+	l := noLimit
+	switch d {
+	case signed:
+		switch r {
+		case lt:
+			l.max = c - 1
+		case lt | eq:
+			l.max = c
+		case gt:
+			l.min = c + 1
+		case gt | eq:
+			l.min = c
+		case eq:
+			l.min, l.max = c, c
+		default:
+			return
+		}
+	case unsigned:
+		uc := uint64(c)
+		switch r {
+		case lt:
+			if uc == 0 {
+				return
+			}
+			l.umax = uc - 1
+		case lt | eq:
+			l.umax = uc
+		case gt:
+			l.umin = uc + 1
+		case gt | eq:
+			l.umin = uc
+		case eq:
+			l.umin, l.umax = uc, uc
+		default:
+			return
 		}
 	}
+	ft.newLimit(v, l)
 }
 
 func lessByID(v, w *Value) bool {
@@ -223,9 +728,27 @@ var (
 		// but the negative branch only learns unsigned/GE.
 		OpIsInBounds:      {unsigned, lt},
 		OpIsSliceInBounds: {unsigned, lt | eq},
+
+		// OpIsNonNil v is a comparison of v against the zero value in
+		// the pointer domain (represented, like booleans, by a nil
+		// *Value for the zero side of the pair): the positive branch
+		// learns lt|gt, i.e. v != nil.
+		OpIsNonNil: {pointer, lt | gt},
 	}
 )
 
+// relationArgs returns the two operands a domainRelationTable
+// comparison is between. Most entries are binary (Args[0], Args[1]);
+// OpIsNonNil is unary and is compared against the zero value, so its
+// second operand is the nil *Value that pair and factsTable already
+// use to mean "the zero value".
+func relationArgs(c *Value) (v, w *Value) {
+	if c.Op == OpIsNonNil {
+		return c.Args[0], nil
+	}
+	return c.Args[0], c.Args[1]
+}
+
 // prove removes redundant BlockIf controls that can be inferred in a straight line.
 //
 // By far, the most common redundant pair are generated by bounds checking.
@@ -270,6 +793,7 @@ func prove(f *Func) {
 	})
 
 	ft := newFactsTable()
+	seedNonNilFacts(f, ft)
 
 	// DFS on the dominator tree.
 	for len(work) > 0 {
@@ -283,11 +807,18 @@ func prove(f *Func) {
 			if branch != unknown {
 				ft.checkpoint()
 				c := parent.Control
-				updateRestrictions(ft, boolean, nil, c, lt|gt, branch)
+				edge := fmt.Sprintf("branch@b%d", parent.ID)
+				updateRestrictions(ft, boolean, nil, c, lt|gt, branch, edge)
 				if tr, has := domainRelationTable[parent.Control.Op]; has {
 					// When we branched from parent we learned a new set of
 					// restrictions. Update the factsTable accordingly.
-					updateRestrictions(ft, tr.d, c.Args[0], c.Args[1], tr.r, branch)
+					v, w := relationArgs(c)
+					updateRestrictions(ft, tr.d, v, w, tr.r, branch, edge)
+				}
+				if branch == positive {
+					if iv := findIndVar(parent, sdom); iv != nil {
+						ft.learnIndVar(iv)
+					}
 				}
 			}
 
@@ -341,9 +872,198 @@ func getBranch(sdom sparseTree, p *Block, b *Block) branch {
 	return unknown
 }
 
+// indVar describes a monotone, phi-based induction variable
+// recognized by findIndVar: ind enters the loop as min and gains a
+// positive constant step each iteration (inc = ind + step), with the
+// loop continuing while ind cmp max holds, in domain d. Note that
+// inc, the back-edge value, is exactly the value tested against max
+// on the loop's next (possibly final) iteration: it is bounded below
+// by min, but is never itself bounded above by max.
+type indVar struct {
+	ind   *Value // the header's phi
+	inc   *Value // ind's value on the back edge: ind + step
+	min   *Value // ind's value on loop entry
+	max   *Value // the loop-invariant exit bound
+	d     domain // signed or unsigned
+	step  int64
+	width int64 // bit width of ind's Add op: 8, 16, 32, or 64
+}
+
+// addWidth reports the bit width of op, which must be one of the
+// OpAddNN opcodes, or 0 if op isn't one of those.
+func addWidth(op Op) int64 {
+	switch op {
+	case OpAdd8:
+		return 8
+	case OpAdd16:
+		return 16
+	case OpAdd32:
+		return 32
+	case OpAdd64:
+		return 64
+	}
+	return 0
+}
+
+// positiveStep reports whether inc computes ind plus a positive
+// constant, returning that constant and the bit width of the add.
+func positiveStep(inc, ind *Value) (step, width int64, ok bool) {
+	width = addWidth(inc.Op)
+	if width == 0 {
+		return 0, 0, false
+	}
+	var other *Value
+	switch ind {
+	case inc.Args[0]:
+		other = inc.Args[1]
+	case inc.Args[1]:
+		other = inc.Args[0]
+	default:
+		return 0, 0, false
+	}
+	step, ok = constInt(other)
+	if !ok || step <= 0 {
+		return 0, 0, false
+	}
+	return step, width, true
+}
+
+// maxSigned and maxUnsigned return the largest signed and unsigned
+// values representable in the given bit width (8, 16, 32, or 64).
+func maxSigned(width int64) int64 {
+	if width >= 64 {
+		return maxInt64
+	}
+	return int64(1)<<uint(width-1) - 1
+}
+
+func maxUnsigned(width int64) uint64 {
+	if width >= 64 {
+		return ^uint64(0)
+	}
+	return uint64(1)<<uint(width) - 1
+}
+
+// overflowSafe reports whether, given max's known upper bound in
+// domain d, adding step to any value less than max is guaranteed not
+// to wrap around a value of the given bit width.
+func (ft *factsTable) overflowSafe(max *Value, step, width int64, d domain) bool {
+	switch d {
+	case signed:
+		return ft.limit(max).max <= maxSigned(width)-step
+	case unsigned:
+		return ft.limit(max).umax <= maxUnsigned(width)-uint64(step)
+	}
+	return false
+}
+
+// findIndVar recognizes b as the header of a natural loop of the
+// shape
+//
+//	for i := min; i cmp max; i += step { ... }
+//
+// where cmp is b's BlockIf control and step is a positive constant,
+// and returns the induction variable it found, or nil if b doesn't
+// match. The loop is recognized directly from the dominator tree
+// already built by prove: b is a loop header if one of its two
+// predecessors is dominated by b itself (the back edge).
+//
+// The facts learnIndVar installs from this only let a bounds check on
+// ind itself (e.g. a[i]) prove without unrolling; a bounds check on a
+// derived expression like a[i+1] is out of scope (see learnIndVar).
+func findIndVar(b *Block, sdom sparseTree) *indVar {
+	if b.Kind != BlockIf || len(b.Preds) != 2 {
+		return nil
+	}
+	c := b.Control
+	tr, has := domainRelationTable[c.Op]
+	if !has || (tr.d != signed && tr.d != unsigned) {
+		return nil
+	}
+
+	entry, latch := 0, 1
+	switch {
+	case sdom.isAncestorEq(b, b.Preds[0]):
+		entry, latch = 1, 0
+	case !sdom.isAncestorEq(b, b.Preds[1]):
+		return nil // neither predecessor is a back edge into b: not a loop
+	}
+
+	for i := 0; i < 2; i++ {
+		ind, max := c.Args[i], c.Args[1-i]
+		if ind.Op != OpPhi || ind.Block != b || len(ind.Args) != 2 {
+			continue
+		}
+		if !sdom.isAncestorEq(max.Block, b) {
+			continue // bound isn't loop-invariant
+		}
+		min, inc := ind.Args[entry], ind.Args[latch]
+		step, width, ok := positiveStep(inc, ind)
+		if !ok {
+			continue
+		}
+		return &indVar{ind: ind, inc: inc, min: min, max: max, d: tr.d, step: step, width: width}
+	}
+	return nil
+}
+
+// learnIndVar installs the facts a recognized induction variable
+// contributes to its loop body. It is always safe to record that ind
+// stays at or above min; combined with the loop header's own branch
+// fact (ind cmp max, already recorded by updateRestrictions before
+// learnIndVar runs), this is what lets a bounds check like a[i] prove
+// without unrolling. Whether the incremented value inc also stays at
+// or above min depends on inc not overflowing, which overflowSafe
+// checks, but that only gives inc a lower bound: inc is never related
+// to max here, so this alone does not let a bounds check on a
+// separate expression like a[i+1] prove. In particular, inc is NOT
+// bounded above by max: inc is exactly the value tested against max
+// on the loop's next, possibly final, iteration, so asserting
+// inc < max here would falsely prove bounds checks on inc that are
+// still live on the last iteration.
+func (ft *factsTable) learnIndVar(iv *indVar) {
+	ft.update(iv.min, iv.ind, iv.d, lt|eq, "indvar")
+	if ft.overflowSafe(iv.max, iv.step, iv.width, iv.d) {
+		ft.update(iv.min, iv.inc, iv.d, lt|eq, "indvar")
+	}
+}
+
+// seedNonNilFacts pre-populates ft with pointer-domain facts for
+// values whose non-nilness is known unconditionally from their
+// defining operation, rather than from an explicit user comparison:
+// the address of a named object, pointer arithmetic on a base already
+// known non-nil, and the result of a fresh allocation. This lets
+// prove remove redundant nil checks introduced by inlining and
+// interface method dispatch even when no `if p != nil` appears in the
+// source. These facts hold for the whole function, so they're
+// installed once, outside the checkpoint/restore scope used for
+// branch-local facts.
+func seedNonNilFacts(f *Func, ft *factsTable) {
+	for _, b := range f.Blocks {
+		for _, v := range b.Values {
+			switch v.Op {
+			case OpAddr:
+				// The address of a named object is never nil.
+				ft.update(v, nil, pointer, lt|gt, "addr")
+			case OpAddPtr:
+				if ft.get(v.Args[0], nil, pointer) == lt|gt {
+					// Offsetting an already non-nil pointer stays non-nil.
+					ft.update(v, nil, pointer, lt|gt, "addptr")
+				}
+			case OpNewObject:
+				// runtime.newobject panics on allocation failure rather
+				// than returning nil, same as the new() builtin it backs.
+				ft.update(v, nil, pointer, lt|gt, "newobject")
+			}
+		}
+	}
+}
+
 // updateRestrictions updates restrictions from the immediate
-// dominating block (p) using r. r is adjusted according to the branch taken.
-func updateRestrictions(ft *factsTable, t domain, v, w *Value, r relation, branch branch) {
+// dominating block (p) using r. r is adjusted according to the
+// branch taken. origin names the dominator edge that taught us this,
+// for citing in a prove trace.
+func updateRestrictions(ft *factsTable, t domain, v, w *Value, r relation, branch branch, origin string) {
 	if t == 0 || branch == unknown {
 		// Trivial case: nothing to do, or branch unknown.
 		// Shoult not happen, but just in case.
@@ -355,11 +1075,210 @@ func updateRestrictions(ft *factsTable, t domain, v, w *Value, r relation, branc
 	}
 	for i := domain(1); i <= t; i <<= 1 {
 		if t&i != 0 {
-			ft.update(v, w, i, r)
+			ft.update(v, w, i, r, origin)
+			if v != nil && w != nil {
+				ft.learnLimit(v, w, i, r)
+			}
 		}
 	}
 }
 
+// cmpLimits returns the set of relations consistent with a signed
+// value known to be in [amin,amax] compared against one known to be
+// in [bmin,bmax].
+func cmpLimits(amin, amax, bmin, bmax int64) relation {
+	if amax < bmin {
+		return lt
+	}
+	if amin > bmax {
+		return gt
+	}
+	r := lt | eq | gt
+	if amax <= bmin {
+		r &^= gt
+	}
+	if amin >= bmax {
+		r &^= lt
+	}
+	return r
+}
+
+// cmpLimitsU is cmpLimits for values known only in their unsigned range.
+func cmpLimitsU(amin, amax, bmin, bmax uint64) relation {
+	if amax < bmin {
+		return lt
+	}
+	if amin > bmax {
+		return gt
+	}
+	r := lt | eq | gt
+	if amax <= bmin {
+		r &^= gt
+	}
+	if amin >= bmax {
+		r &^= lt
+	}
+	return r
+}
+
+// relationFromLimits returns the set of relations between v and w
+// that are consistent with their known intervals in domain d.
+func (ft *factsTable) relationFromLimits(v, w *Value, d domain) relation {
+	lv, lw := ft.limit(v), ft.limit(w)
+	switch d {
+	case signed:
+		return cmpLimits(lv.min, lv.max, lw.min, lw.max)
+	case unsigned:
+		return cmpLimitsU(lv.umin, lv.umax, lw.umin, lw.umax)
+	}
+	return lt | eq | gt
+}
+
+// proveTraceEnabled reports whether prove should emit a machine-
+// readable JSON record, one per line on stderr, for every branch it
+// proves or disproves. It is controlled by the GOSSAPROVE=trace
+// environment variable (the same switch -d=prove=trace is meant to
+// set once that debug flag is wired up by the surrounding compiler
+// driver), so external tools can audit which bounds checks the
+// compiler eliminated and why.
+func proveTraceEnabled() bool {
+	return os.Getenv("GOSSAPROVE") == "trace"
+}
+
+// proveTraceRecord is the schema emitted by emitProveTrace.
+type proveTraceRecord struct {
+	Block  int64            `json:"block"`
+	Line   string           `json:"line"`
+	Op     string           `json:"op"`
+	Result string           `json:"result"` // "proved" or "disproved"
+	Facts  []proveTraceFact `json:"facts"`
+}
+
+// proveTraceFact cites one directly-learned fact (from a dominator
+// edge, an induction variable, or an interval) consulted, possibly
+// through a chain of transitive compositions, to reach the verdict
+// recorded in the enclosing proveTraceRecord.
+type proveTraceFact struct {
+	V          int64  `json:"v"`
+	W          int64  `json:"w"`
+	Domain     string `json:"domain"`
+	Relation   string `json:"relation"`
+	Derivation string `json:"derivation"` // e.g. "branch@b12", "indvar", "interval"
+}
+
+func domainString(d domain) string {
+	switch d {
+	case signed:
+		return "signed"
+	case unsigned:
+		return "unsigned"
+	case pointer:
+		return "pointer"
+	case boolean:
+		return "boolean"
+	}
+	return "unknown"
+}
+
+func relationString(r relation) string {
+	s := ""
+	if r&lt != 0 {
+		s += "<"
+	}
+	if r&eq != 0 {
+		s += "="
+	}
+	if r&gt != 0 {
+		s += ">"
+	}
+	if s == "" {
+		s = "none"
+	}
+	return s
+}
+
+// traceChain returns the chain of directly-learned facts that justify
+// the current relation between v and w in domain d, walking back
+// through any transitive compositions (factInfo.parents) to their
+// roots. The result may cite more than one fact: e.g. a bound proved
+// via a < b and b <= c yields both of those, not a single collapsed
+// "transitive" fact.
+func (ft *factsTable) traceChain(v, w *Value, d domain) []proveTraceFact {
+	if lessByID(w, v) {
+		v, w = w, v
+	}
+	var facts []proveTraceFact
+	seen := make(map[pair]bool)
+	var walk func(p pair)
+	walk = func(p pair) {
+		if seen[p] {
+			return
+		}
+		seen[p] = true
+		fi := ft.facts[p]
+		if !fi.trans {
+			// fi.r only reflects what's recorded in ft.facts; a pair
+			// consulted solely through relationFromLimits (no entry in
+			// ft.facts at all) would otherwise report the zero relation
+			// "none" here. Recompute the same way simplifyBlock does,
+			// combining ft.facts with the interval-derived relation.
+			r := ft.get(p.v, p.w, p.d) & ft.relationFromLimits(p.v, p.w, p.d)
+			origin := fi.origin
+			if origin == "" {
+				origin = "interval"
+			}
+			facts = append(facts, proveTraceFact{
+				V:          valueID(p.v),
+				W:          valueID(p.w),
+				Domain:     domainString(p.d),
+				Relation:   relationString(r),
+				Derivation: origin,
+			})
+			return
+		}
+		for _, parent := range fi.parents {
+			walk(parent)
+		}
+	}
+	walk(pair{v, w, d})
+	return facts
+}
+
+// emitProveTrace writes a proveTraceRecord describing why b's branch
+// was proved or disproved, citing the chain of facts consulted from ft
+// to reach that verdict for (v, w, d). It is a no-op unless
+// proveTraceEnabled.
+func emitProveTrace(ft *factsTable, b *Block, result branch, v, w *Value, d domain) {
+	if !proveTraceEnabled() {
+		return
+	}
+	rec := proveTraceRecord{
+		Block: int64(b.ID),
+		Line:  fmt.Sprint(b.Line),
+		Op:    fmt.Sprint(b.Control.Op),
+	}
+	if result == positive {
+		rec.Result = "proved"
+	} else {
+		rec.Result = "disproved"
+	}
+	rec.Facts = ft.traceChain(v, w, d)
+	if data, err := json.Marshal(rec); err == nil {
+		fmt.Fprintln(os.Stderr, string(data))
+	}
+}
+
+// valueID returns v's ID, or -1 for the nil *Value that pair,
+// factsTable and relationArgs use as a sentinel for the zero value
+// (of a boolean or, for OpIsNonNil, a pointer), so that traces can
+// still cite facts involving it.
+func valueID(v *Value) int64 {
+	if v == nil {
+		return -1
+	}
+	return int64(v.ID)
+}
+
 // simplifyBlock simplifies block known the restrictions in ft.
 // Returns which branch must always be taken.
 func simplifyBlock(ft *factsTable, b *Block) branch {
@@ -373,12 +1292,14 @@ func simplifyBlock(ft *factsTable, b *Block) branch {
 		if b.Func.pass.debug > 0 {
 			b.Func.Config.Warnl(b.Line, "Proved boolean %s", b.Control.Op)
 		}
+		emitProveTrace(ft, b, positive, nil, b.Control, boolean)
 		return positive
 	}
 	if m == eq {
 		if b.Func.pass.debug > 0 {
 			b.Func.Config.Warnl(b.Line, "Disproved boolean %s", b.Control.Op)
 		}
+		emitProveTrace(ft, b, negative, nil, b.Control, boolean)
 		return negative
 	}
 
@@ -389,7 +1310,7 @@ func simplifyBlock(ft *factsTable, b *Block) branch {
 		return unknown
 	}
 
-	a0, a1 := c.Args[0], c.Args[1]
+	a0, a1 := relationArgs(c)
 	for d := domain(1); d <= tr.d; d <<= 1 {
 		if d&tr.d == 0 {
 			continue
@@ -401,17 +1322,22 @@ func simplifyBlock(ft *factsTable, b *Block) branch {
 		// need to take the positive branch (or negative) then that branch will
 		// always be taken.
 		// For shortcut, if m == 0 then this block is dead code.
-		m := ft.get(a0, a1, d)
+		// m is further narrowed by whatever a0 and a1's known intervals
+		// imply, so bounds checks on values like a[i&mask] can be proved
+		// without an explicit dominating comparison.
+		m := ft.get(a0, a1, d) & ft.relationFromLimits(a0, a1, d)
 		if m != 0 && tr.r&m == m {
 			if b.Func.pass.debug > 0 {
 				b.Func.Config.Warnl(b.Line, "Proved %s", c.Op)
 			}
+			emitProveTrace(ft, b, positive, a0, a1, d)
 			return positive
 		}
 		if m != 0 && ((lt|eq|gt)^tr.r)&m == m {
 			if b.Func.pass.debug > 0 {
 				b.Func.Config.Warnl(b.Line, "Disproved %s", c.Op)
 			}
+			emitProveTrace(ft, b, negative, a0, a1, d)
 			return negative
 		}
 	}
@@ -421,12 +1347,13 @@ func simplifyBlock(ft *factsTable, b *Block) branch {
 	// to the upper bound than this is proven. Most useful in cases such as:
 	// if len(a) <= 1 { return }
 	// do something with a[1]
-	if (c.Op == OpIsInBounds || c.Op == OpIsSliceInBounds) && isNonNegative(c.Args[0]) {
+	if (c.Op == OpIsInBounds || c.Op == OpIsSliceInBounds) && ft.isNonNegative(c.Args[0]) {
 		m := ft.get(a0, a1, signed)
 		if m != 0 && tr.r&m == m {
 			if b.Func.pass.debug > 0 {
 				b.Func.Config.Warnl(b.Line, "Proved non-negative bounds %s", c.Op)
 			}
+			emitProveTrace(ft, b, positive, a0, a1, signed)
 			return positive
 		}
 	}
@@ -435,7 +1362,10 @@ func simplifyBlock(ft *factsTable, b *Block) branch {
 }
 
 // isNonNegative returns true is v is known to be greater or equal to zero.
-func isNonNegative(v *Value) bool {
+func (ft *factsTable) isNonNegative(v *Value) bool {
+	if ft.limit(v).min >= 0 {
+		return true
+	}
 	switch v.Op {
 	case OpConst64:
 		return v.AuxInt >= 0
@@ -445,7 +1375,7 @@ func isNonNegative(v *Value) bool {
 		return true
 
 	case OpRsh64x64:
-		return isNonNegative(v.Args[0])
+		return ft.isNonNegative(v.Args[0])
 	}
 	return false
 }